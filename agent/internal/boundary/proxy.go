@@ -0,0 +1,71 @@
+package boundary
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+
+	"github.com/hashicorp/boundary/api/proxy"
+	"github.com/hashicorp/boundary/api/targets"
+)
+
+// sessionProxy wraps the SDK's websocket-based client proxy, exposing just
+// the local port ConnectRDP needs and a Close that tears the whole session
+// down.
+type sessionProxy struct {
+	localPort int
+	client    *proxy.ClientProxy
+	cancel    context.CancelFunc
+}
+
+// startProxy authorizes and starts the SDK client proxy for the given
+// session, listening on 127.0.0.1 on an OS-assigned port. The real worker
+// dial, mTLS, and byte pumping all live inside proxy.ClientProxy; we only
+// need to stand it up and hand back where it's listening.
+func startProxy(ctx context.Context, authzData *targets.SessionAuthorizationData) (*sessionProxy, error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	client, err := proxy.New(ctx,
+		proxy.WithSessionAuthorizationData(authzData),
+		proxy.WithListenAddrPort(netip.MustParseAddrPort("127.0.0.1:0")),
+	)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("cannot create session proxy: %w", err)
+	}
+
+	if err := client.Start(ctx); err != nil {
+		cancel()
+		return nil, fmt.Errorf("cannot start session proxy: %w", err)
+	}
+
+	addr, err := client.ListenerAddress(ctx)
+	if err != nil {
+		cancel()
+		client.Close()
+		return nil, fmt.Errorf("cannot determine local proxy address: %w", err)
+	}
+
+	_, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		cancel()
+		client.Close()
+		return nil, fmt.Errorf("unexpected proxy listener address %q: %w", addr, err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		cancel()
+		client.Close()
+		return nil, fmt.Errorf("unexpected proxy listener port %q: %w", portStr, err)
+	}
+
+	return &sessionProxy{localPort: port, client: client, cancel: cancel}, nil
+}
+
+// Close stops the session proxy and releases its listener.
+func (p *sessionProxy) Close() error {
+	p.cancel()
+	return p.client.Close()
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// UpdaterPubKey is the hex-encoded Ed25519 public key used to verify
+// detached signatures on downloaded agent updates. Baked in at build time
+// via ldflags.
+var UpdaterPubKey = ""
+
+// autoApplyFlagPath returns the location of the marker file operators drop
+// to opt a given install into unattended updates.
+func autoApplyFlagPath() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "kamvdi", "auto-apply"), nil
+}
+
+// AutoApply reports whether this install should apply updates automatically
+// instead of only notifying. Off by default — operators opt in explicitly.
+func AutoApply() bool {
+	path, err := autoApplyFlagPath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// SetAutoApply persists the AutoApply opt-in/out for this install.
+func SetAutoApply(enabled bool) error {
+	path, err := autoApplyFlagPath()
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		err := os.Remove(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, nil, 0o600)
+}
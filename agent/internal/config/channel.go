@@ -0,0 +1,102 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// StableChannel, BetaChannel and NightlyChannel are the release channels an
+// install can subscribe to. Stable is the default for every install that
+// hasn't explicitly opted into a pre-release channel.
+const (
+	StableChannel  = "stable"
+	BetaChannel    = "beta"
+	NightlyChannel = "nightly"
+)
+
+// ArmVersion disambiguates 32-bit ARM asset selection (e.g. "6", "7").
+// Baked in at build time via ldflags; irrelevant on every other GOARCH.
+var ArmVersion = "7"
+
+func updateChannelPath() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(base, "kamvdi")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "update-channel.txt"), nil
+}
+
+// UpdateChannel returns the release channel this install tracks, defaulting
+// to StableChannel if none has been selected.
+func UpdateChannel() string {
+	path, err := updateChannelPath()
+	if err != nil {
+		return StableChannel
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return StableChannel
+	}
+	channel := strings.TrimSpace(string(data))
+	if channel == "" {
+		return StableChannel
+	}
+	return channel
+}
+
+// SetUpdateChannel persists the release channel this install should track.
+func SetUpdateChannel(channel string) error {
+	path, err := updateChannelPath()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(channel), 0o600)
+}
+
+func pinnedVersionPath() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(base, "kamvdi")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "pinned-version.txt"), nil
+}
+
+// PinnedVersion returns the version this install is pinned to, or "" if
+// it should follow the latest release on its channel.
+func PinnedVersion() string {
+	path, err := pinnedVersionPath()
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// SetPinnedVersion persists a version to pin to, or clears the pin when
+// version is "".
+func SetPinnedVersion(version string) error {
+	path, err := pinnedVersionPath()
+	if err != nil {
+		return err
+	}
+	if version == "" {
+		err := os.Remove(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return os.WriteFile(path, []byte(version), 0o600)
+}
@@ -3,6 +3,11 @@ package config
 // AgentVersion is set at build time via ldflags or from main.
 var AgentVersion = "dev"
 
+// WSLTarballPubKey is the hex-encoded Ed25519 public key used to verify the
+// signed manifest for the kamvdi-client WSL2 distro tarball. Baked in at
+// build time via ldflags.
+var WSLTarballPubKey = ""
+
 const (
 	// URIScheme is the custom protocol scheme.
 	URIScheme = "kamvdi"
@@ -12,7 +17,4 @@ const (
 
 	// UpdateCheckIntervalHours controls auto-update polling.
 	UpdateCheckIntervalHours = 24
-
-	// BoundaryBinary is the expected name of the Boundary CLI.
-	BoundaryBinary = "boundary"
 )
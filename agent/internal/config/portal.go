@@ -0,0 +1,46 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// portalURLPath returns the location of the persisted portal URL, written
+// during --register so update paths (background checks, `cwmvdi update`,
+// POST /api/update/apply) know which portal to talk to without requiring a
+// kamvdi:// URI first.
+func portalURLPath() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(base, "kamvdi")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "portal-url.txt"), nil
+}
+
+// SavePortalURL persists the portal URL used during --register.
+func SavePortalURL(portalURL string) error {
+	path, err := portalURLPath()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(portalURL), 0o600)
+}
+
+// LoadPortalURL returns the persisted portal URL, or "" if none has been
+// saved yet.
+func LoadPortalURL() string {
+	path, err := portalURLPath()
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
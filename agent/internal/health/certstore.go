@@ -0,0 +1,237 @@
+package health
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/itzik715-cmd/kamatera-vdi/agent/internal/keyring"
+)
+
+// Hostname is the name the per-install leaf certificate is issued for.
+const Hostname = "agent.localhost.kamvdi.local"
+
+// keyringService is the keyring entry name the CA private key is sealed
+// under.
+const keyringService = "kamvdi-ca-key"
+
+// leafLifetime is the validity period of an issued leaf certificate. Start
+// rotates the leaf once less than a third of it remains.
+const leafLifetime = 90 * 24 * time.Hour
+
+// certDir returns the per-user directory the CA and leaf material lives in.
+func certDir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine config dir: %w", err)
+	}
+	dir := filepath.Join(base, "kamvdi", "certs")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("cannot create cert dir: %w", err)
+	}
+	return dir, nil
+}
+
+// EnsureCA generates the per-install root CA and installs it into the trust
+// store, unless one has already been provisioned. Called from --register.
+func EnsureCA() error {
+	dir, err := certDir()
+	if err != nil {
+		return err
+	}
+	caCertPath := filepath.Join(dir, "ca.crt")
+
+	if _, err := os.Stat(caCertPath); err == nil {
+		return nil // already provisioned
+	}
+
+	caCertDER, caKey, err := generateCA()
+	if err != nil {
+		return fmt.Errorf("cannot generate root CA: %w", err)
+	}
+
+	keyBytes, err := x509.MarshalECPrivateKey(caKey)
+	if err != nil {
+		return fmt.Errorf("cannot marshal CA key: %w", err)
+	}
+	sealed, err := keyring.Seal(keyringService, keyBytes)
+	if err != nil {
+		return fmt.Errorf("cannot seal CA key in platform keyring: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ca.key.sealed"), sealed, 0o600); err != nil {
+		return fmt.Errorf("cannot persist sealed CA key: %w", err)
+	}
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCertDER})
+	if err := os.WriteFile(caCertPath, caPEM, 0o600); err != nil {
+		return fmt.Errorf("cannot persist CA certificate: %w", err)
+	}
+
+	if err := installTrustRoot(caCertDER); err != nil {
+		return fmt.Errorf("cannot install root CA into system trust store: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveCA removes the per-install root CA from the trust store and the
+// keyring. Called from --unregister.
+func RemoveCA() error {
+	dir, err := certDir()
+	if err != nil {
+		return err
+	}
+
+	if err := removeTrustRoot(); err != nil {
+		return fmt.Errorf("cannot remove root CA from trust store: %w", err)
+	}
+
+	if err := keyring.Remove(keyringService); err != nil {
+		return fmt.Errorf("cannot remove CA key from platform keyring: %w", err)
+	}
+
+	return os.RemoveAll(dir)
+}
+
+// leafTLSConfig loads the current leaf certificate, rotating it first if
+// less than leafLifetime/3 of its validity remains (or it doesn't exist yet).
+func leafTLSConfig() (*tls.Config, error) {
+	dir, err := certDir()
+	if err != nil {
+		return nil, err
+	}
+
+	leafCertPath := filepath.Join(dir, "leaf.crt")
+	leafKeyPath := filepath.Join(dir, "leaf.key")
+
+	needsRotation := true
+	if cert, err := tls.LoadX509KeyPair(leafCertPath, leafKeyPath); err == nil {
+		if leaf, err := x509.ParseCertificate(cert.Certificate[0]); err == nil {
+			if time.Until(leaf.NotAfter) > leafLifetime/3 {
+				needsRotation = false
+			}
+		}
+	}
+
+	if needsRotation {
+		if err := rotateLeaf(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	cert, err := tls.LoadX509KeyPair(leafCertPath, leafKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load rotated leaf certificate: %w", err)
+	}
+
+	return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+}
+
+// rotateLeaf issues a fresh leaf certificate signed by the per-install CA.
+func rotateLeaf(dir string) error {
+	caCertPEM, err := os.ReadFile(filepath.Join(dir, "ca.crt"))
+	if err != nil {
+		return fmt.Errorf("cannot read root CA: %w", err)
+	}
+	caBlock, _ := pem.Decode(caCertPEM)
+	if caBlock == nil {
+		return fmt.Errorf("root CA certificate is malformed")
+	}
+	caCert, err := x509.ParseCertificate(caBlock.Bytes)
+	if err != nil {
+		return fmt.Errorf("cannot parse root CA: %w", err)
+	}
+
+	sealed, err := os.ReadFile(filepath.Join(dir, "ca.key.sealed"))
+	if err != nil {
+		return fmt.Errorf("cannot read sealed CA key: %w", err)
+	}
+	keyBytes, err := keyring.Open(keyringService, sealed)
+	if err != nil {
+		return fmt.Errorf("cannot unseal CA key: %w", err)
+	}
+	caKey, err := x509.ParseECPrivateKey(keyBytes)
+	if err != nil {
+		return fmt.Errorf("cannot parse CA key: %w", err)
+	}
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("cannot generate leaf key: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return fmt.Errorf("cannot generate serial: %w", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: Hostname},
+		DNSNames:     []string{Hostname, "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(leafLifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	leafDER, err := x509.CreateCertificate(rand.Reader, template, caCert, &leafKey.PublicKey, caKey)
+	if err != nil {
+		return fmt.Errorf("cannot issue leaf certificate: %w", err)
+	}
+
+	leafKeyDER, err := x509.MarshalECPrivateKey(leafKey)
+	if err != nil {
+		return fmt.Errorf("cannot marshal leaf key: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "leaf.crt"),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDER}), 0o600); err != nil {
+		return fmt.Errorf("cannot persist leaf certificate: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "leaf.key"),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: leafKeyDER}), 0o600); err != nil {
+		return fmt.Errorf("cannot persist leaf key: %w", err)
+	}
+
+	return nil
+}
+
+// generateCA creates a new self-signed ECDSA root CA.
+func generateCA() ([]byte, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "KamVDI Agent Local Root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return der, key, nil
+}
@@ -6,11 +6,15 @@ import (
 	"net/http"
 
 	"github.com/itzik715-cmd/kamatera-vdi/agent/internal/config"
+	"github.com/itzik715-cmd/kamatera-vdi/agent/internal/updater"
 )
 
 const ListenAddr = "127.0.0.1:17715"
 
-// Start runs a local HTTP health server so the browser can detect the agent.
+// Start runs a local HTTPS health server so the browser can detect the
+// agent without tripping mixed-content blocking. It serves a per-install
+// leaf certificate signed by the root CA provisioned during --register,
+// rotating the leaf on startup once it nears expiry.
 func Start() {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -28,9 +32,23 @@ func Start() {
 			"version": config.AgentVersion,
 		})
 	})
+	mux.HandleFunc("/api/update/status", updater.StatusHandler)
+	mux.HandleFunc("/api/update/apply", updater.ApplyHandler)
+
+	tlsConfig, err := leafTLSConfig()
+	if err != nil {
+		log.Printf("Health server: cannot provision TLS certificate (run --register first?): %v", err)
+		return
+	}
+
+	server := &http.Server{
+		Addr:      ListenAddr,
+		Handler:   mux,
+		TLSConfig: tlsConfig,
+	}
 
 	go func() {
-		if err := http.ListenAndServe(ListenAddr, mux); err != nil {
+		if err := server.ListenAndServeTLS("", ""); err != nil {
 			log.Printf("Health server failed: %v", err)
 		}
 	}()
@@ -0,0 +1,12 @@
+package health
+
+// installTrustRoot adds caCertDER to the user's trust store so browsers
+// accept the agent's per-install HTTPS certificate.
+func installTrustRoot(caCertDER []byte) error {
+	return platformInstallTrustRoot(caCertDER)
+}
+
+// removeTrustRoot removes the per-install root CA from the trust store.
+func removeTrustRoot() error {
+	return platformRemoveTrustRoot()
+}
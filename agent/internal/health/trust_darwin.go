@@ -0,0 +1,80 @@
+//go:build darwin
+
+package health
+
+import (
+	"encoding/pem"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+)
+
+// platformInstallTrustRoot adds the root CA to the login keychain as a
+// trusted root via the `security` CLI.
+func platformInstallTrustRoot(caCertDER []byte) error {
+	tmpFile, err := os.CreateTemp("", "kamvdi-ca-*.crt")
+	if err != nil {
+		return fmt.Errorf("cannot create temp cert file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCertDER})
+	if _, err := tmpFile.Write(pemBytes); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("cannot write temp cert file: %w", err)
+	}
+	tmpFile.Close()
+
+	keychain, err := loginKeychainPath()
+	if err != nil {
+		return err
+	}
+
+	// No -d: that flag records trust settings in the admin domain, which
+	// requires admin authorization. -k <login keychain> already scopes this
+	// to the per-user domain, matching the unattended, no-admin install.
+	cmd := exec.Command("security", "add-trusted-cert", "-r", "trustRoot",
+		"-k", keychain, tmpFile.Name())
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("security add-trusted-cert failed: %w (%s)", err, out)
+	}
+
+	return nil
+}
+
+// platformRemoveTrustRoot removes the root CA from the login keychain.
+func platformRemoveTrustRoot() error {
+	dir, err := certDir()
+	if err != nil {
+		return err
+	}
+
+	caCertPath := filepath.Join(dir, "ca.crt")
+	keychain, err := loginKeychainPath()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("security", "remove-trusted-cert", caCertPath)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		// Fall back to deleting by keychain-wide identity if the on-disk
+		// copy is already gone.
+		cmd = exec.Command("security", "delete-certificate", "-c", "KamVDI Agent Local Root", keychain)
+		if out2, err2 := cmd.CombinedOutput(); err2 != nil {
+			return fmt.Errorf("security remove-trusted-cert failed: %w (%s); fallback also failed: %v (%s)",
+				err, out, err2, out2)
+		}
+	}
+
+	return nil
+}
+
+func loginKeychainPath() (string, error) {
+	u, err := user.Current()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine current user: %w", err)
+	}
+	return filepath.Join(u.HomeDir, "Library", "Keychains", "login.keychain-db"), nil
+}
@@ -0,0 +1,18 @@
+//go:build !windows && !darwin
+
+package health
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// platformInstallTrustRoot and platformRemoveTrustRoot have no
+// implementation outside Windows (CurrentUser\Root) and macOS (Keychain).
+func platformInstallTrustRoot(caCertDER []byte) error {
+	return fmt.Errorf("health: unsupported OS: %s", runtime.GOOS)
+}
+
+func platformRemoveTrustRoot() error {
+	return fmt.Errorf("health: unsupported OS: %s", runtime.GOOS)
+}
@@ -0,0 +1,104 @@
+//go:build windows
+
+package health
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// certStoreName is the CurrentUser\Root store, so no admin rights are
+// required (mirrors registration's HKCU-only approach).
+const certStoreName = "Root"
+
+// platformInstallTrustRoot adds the root CA to CurrentUser\Root via the
+// certificate store APIs in crypt32.dll.
+func platformInstallTrustRoot(caCertDER []byte) error {
+	storeNamePtr, err := windows.UTF16PtrFromString(certStoreName)
+	if err != nil {
+		return err
+	}
+
+	store, err := windows.CertOpenStore(
+		windows.CERT_STORE_PROV_SYSTEM,
+		0,
+		0,
+		windows.CERT_SYSTEM_STORE_CURRENT_USER,
+		uintptr(unsafe.Pointer(storeNamePtr)),
+	)
+	if err != nil {
+		return fmt.Errorf("CertOpenStore failed: %w", err)
+	}
+	defer windows.CertCloseStore(store, 0)
+
+	ctx, err := windows.CertCreateCertificateContext(
+		windows.X509_ASN_ENCODING|windows.PKCS_7_ASN_ENCODING,
+		&caCertDER[0],
+		uint32(len(caCertDER)),
+	)
+	if err != nil {
+		return fmt.Errorf("CertCreateCertificateContext failed: %w", err)
+	}
+	defer windows.CertFreeCertificateContext(ctx)
+
+	if err := windows.CertAddCertificateContextToStore(
+		store, ctx, windows.CERT_STORE_ADD_REPLACE_EXISTING, nil,
+	); err != nil {
+		return fmt.Errorf("CertAddCertificateContextToStore failed: %w", err)
+	}
+
+	return nil
+}
+
+// platformRemoveTrustRoot removes the KamVDI root CA from CurrentUser\Root.
+func platformRemoveTrustRoot() error {
+	dir, err := certDir()
+	if err != nil {
+		return err
+	}
+	_ = dir // the cert bytes are deleted from disk by RemoveCA; the store
+	// entry is located by subject when walking the store below.
+
+	storeNamePtr, err := windows.UTF16PtrFromString(certStoreName)
+	if err != nil {
+		return err
+	}
+
+	store, err := windows.CertOpenStore(
+		windows.CERT_STORE_PROV_SYSTEM,
+		0,
+		0,
+		windows.CERT_SYSTEM_STORE_CURRENT_USER,
+		uintptr(unsafe.Pointer(storeNamePtr)),
+	)
+	if err != nil {
+		return fmt.Errorf("CertOpenStore failed: %w", err)
+	}
+	defer windows.CertCloseStore(store, 0)
+
+	var prev *windows.CertContext
+	for {
+		ctx, err := windows.CertFindCertificateInStore(
+			store,
+			windows.X509_ASN_ENCODING|windows.PKCS_7_ASN_ENCODING,
+			0,
+			windows.CERT_FIND_SUBJECT_STR,
+			stringPtr("KamVDI Agent Local Root"),
+			prev,
+		)
+		if err != nil {
+			break // no more matches
+		}
+		windows.CertDeleteCertificateFromStore(ctx)
+		prev = nil
+	}
+
+	return nil
+}
+
+func stringPtr(s string) unsafe.Pointer {
+	p, _ := windows.UTF16PtrFromString(s)
+	return unsafe.Pointer(p)
+}
@@ -0,0 +1,19 @@
+// Package keyring seals small secrets (CA private keys, shared HMAC
+// secrets) at rest using the platform's credential store: DPAPI on
+// Windows, Keychain on macOS.
+package keyring
+
+// Seal encrypts data for the current user under the given service name.
+func Seal(service string, data []byte) ([]byte, error) {
+	return platformSeal(service, data)
+}
+
+// Open decrypts data previously sealed with Seal under the same service name.
+func Open(service string, sealed []byte) ([]byte, error) {
+	return platformOpen(service, sealed)
+}
+
+// Remove deletes the keyring entry for the given service, if any.
+func Remove(service string) error {
+	return platformRemove(service)
+}
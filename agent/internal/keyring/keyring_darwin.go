@@ -0,0 +1,55 @@
+//go:build darwin
+
+package keyring
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+const keychainAccount = "kamvdi-agent"
+
+// platformSeal stores data (base64-encoded) as a generic password in the
+// login keychain, keyed by service.
+func platformSeal(service string, data []byte) ([]byte, error) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	// -U updates the item in place if it already exists.
+	cmd := exec.Command("security", "add-generic-password",
+		"-a", keychainAccount, "-s", service, "-w", encoded, "-U")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("security add-generic-password failed: %w (%s)", err, out)
+	}
+
+	// The keyring is the source of truth; nothing needs to live on disk.
+	return []byte(service), nil
+}
+
+// platformOpen retrieves the secret previously sealed with platformSeal.
+// sealed is ignored; the keychain entry is looked up by account/service.
+func platformOpen(service string, sealed []byte) ([]byte, error) {
+	cmd := exec.Command("security", "find-generic-password",
+		"-a", keychainAccount, "-s", service, "-w")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("security find-generic-password failed: %w", err)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(out)))
+	if err != nil {
+		return nil, fmt.Errorf("keychain entry is malformed: %w", err)
+	}
+	return decoded, nil
+}
+
+// platformRemove deletes the keychain entry for service.
+func platformRemove(service string) error {
+	cmd := exec.Command("security", "delete-generic-password",
+		"-a", keychainAccount, "-s", service)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("security delete-generic-password failed: %w (%s)", err, out)
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+//go:build !windows && !darwin
+
+package keyring
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// platformSeal, platformOpen and platformRemove have no implementation
+// outside Windows (DPAPI) and macOS (Keychain).
+func platformSeal(service string, data []byte) ([]byte, error) {
+	return nil, fmt.Errorf("keyring: unsupported OS: %s", runtime.GOOS)
+}
+
+func platformOpen(service string, sealed []byte) ([]byte, error) {
+	return nil, fmt.Errorf("keyring: unsupported OS: %s", runtime.GOOS)
+}
+
+func platformRemove(service string) error {
+	return fmt.Errorf("keyring: unsupported OS: %s", runtime.GOOS)
+}
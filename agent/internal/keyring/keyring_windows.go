@@ -0,0 +1,75 @@
+//go:build windows
+
+package keyring
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	crypt32DLL             = windows.NewLazySystemDLL("crypt32.dll")
+	procCryptProtectData   = crypt32DLL.NewProc("CryptProtectData")
+	procCryptUnprotectData = crypt32DLL.NewProc("CryptUnprotectData")
+)
+
+type dataBlob struct {
+	cbData uint32
+	pbData *byte
+}
+
+func newBlob(b []byte) *dataBlob {
+	if len(b) == 0 {
+		return &dataBlob{}
+	}
+	return &dataBlob{cbData: uint32(len(b)), pbData: &b[0]}
+}
+
+// platformSeal encrypts data for the current user via DPAPI
+// (CryptProtectData), so the CA private key never touches disk in plaintext.
+// DPAPI has no notion of a service name; the blob is scoped by wherever the
+// caller persists it.
+func platformSeal(service string, data []byte) ([]byte, error) {
+	in := newBlob(data)
+	var out dataBlob
+
+	ret, _, err := procCryptProtectData.Call(
+		uintptr(unsafe.Pointer(in)),
+		0, 0, 0, 0, 0,
+		uintptr(unsafe.Pointer(&out)),
+	)
+	if ret == 0 {
+		return nil, fmt.Errorf("CryptProtectData failed: %w", err)
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.pbData)))
+
+	return unsafe.Slice(out.pbData, out.cbData), nil
+}
+
+// platformOpen decrypts data previously sealed with platformSeal.
+func platformOpen(service string, sealed []byte) ([]byte, error) {
+	in := newBlob(sealed)
+	var out dataBlob
+
+	ret, _, err := procCryptUnprotectData.Call(
+		uintptr(unsafe.Pointer(in)),
+		0, 0, 0, 0, 0,
+		uintptr(unsafe.Pointer(&out)),
+	)
+	if ret == 0 {
+		return nil, fmt.Errorf("CryptUnprotectData failed: %w", err)
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.pbData)))
+
+	decrypted := make([]byte, out.cbData)
+	copy(decrypted, unsafe.Slice(out.pbData, out.cbData))
+	return decrypted, nil
+}
+
+// platformRemove is a no-op on Windows: DPAPI blobs live wherever the
+// caller persisted them, and there's no separate store entry to delete.
+func platformRemove(service string) error {
+	return nil
+}
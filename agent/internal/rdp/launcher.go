@@ -6,26 +6,86 @@ import (
 	"runtime"
 )
 
-// LaunchDirect opens the native RDP client connecting to a remote host directly.
-func LaunchDirect(host string, port int) error {
+// Supported desktop protocols.
+const (
+	ProtocolRDP     = "rdp"
+	ProtocolX11     = "x11"
+	ProtocolWayland = "wayland"
+)
+
+// ConnectParams describes the local endpoint and desktop protocol to launch
+// a native client against.
+type ConnectParams struct {
+	Host      string
+	Port      int
+	Protocol  string
+	PortalURL string
+}
+
+// LaunchDirect opens the native client for params.Protocol, returning the
+// client process so callers can wait on it to know when the user has
+// disconnected.
+func LaunchDirect(params ConnectParams) (*exec.Cmd, error) {
+	switch params.Protocol {
+	case "", ProtocolRDP:
+		return launchRDP(params)
+	case ProtocolX11, ProtocolWayland:
+		return launchLinuxDesktop(params)
+	default:
+		return nil, fmt.Errorf("unsupported protocol: %s", params.Protocol)
+	}
+}
+
+func launchRDP(params ConnectParams) (*exec.Cmd, error) {
+	switch runtime.GOOS {
+	case "windows":
+		return launchWindowsDirect(params.Host, params.Port)
+	case "darwin":
+		return launchMacDirect(params.Host, params.Port)
+	default:
+		return nil, fmt.Errorf("unsupported OS: %s", runtime.GOOS)
+	}
+}
+
+// launchLinuxDesktop opens an X11/Wayland session against a Linux VDI
+// target. On Windows this runs xfreerdp inside a lightweight WSL2 distro
+// (WSLg provides the display); on macOS it runs xfreerdp under XQuartz.
+func launchLinuxDesktop(params ConnectParams) (*exec.Cmd, error) {
 	switch runtime.GOOS {
 	case "windows":
-		return launchWindowsDirect(host, port)
+		return launchWSLDesktop(params)
 	case "darwin":
-		return launchMacDirect(host, port)
+		return launchXQuartzDesktop(params)
 	default:
-		return fmt.Errorf("unsupported OS: %s", runtime.GOOS)
+		return nil, fmt.Errorf("unsupported OS for Linux desktop target: %s", runtime.GOOS)
 	}
 }
 
-func launchWindowsDirect(host string, port int) error {
+func launchWindowsDirect(host string, port int) (*exec.Cmd, error) {
 	addr := fmt.Sprintf("/v:%s:%d", host, port)
 	cmd := exec.Command("mstsc", addr)
-	return cmd.Start()
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return cmd, nil
 }
 
-func launchMacDirect(host string, port int) error {
+func launchMacDirect(host string, port int) (*exec.Cmd, error) {
 	uri := fmt.Sprintf("rdp://full%%20address=s:%s:%d", host, port)
 	cmd := exec.Command("open", uri)
-	return cmd.Start()
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// launchXQuartzDesktop shells out to a locally installed xfreerdp (typically
+// provided via Homebrew alongside XQuartz) to reach a Linux desktop target.
+func launchXQuartzDesktop(params ConnectParams) (*exec.Cmd, error) {
+	addr := fmt.Sprintf("/v:%s:%d", params.Host, params.Port)
+	cmd := exec.Command("xfreerdp", addr)
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("xfreerdp not found — install it via Homebrew/XQuartz: %w", err)
+	}
+	return cmd, nil
 }
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package rdp
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// launchWSLDesktop only applies on Windows, where WSLg provides the display
+// for xfreerdp.
+func launchWSLDesktop(params ConnectParams) (*exec.Cmd, error) {
+	return nil, fmt.Errorf("WSL-backed Linux desktop target is only supported on Windows")
+}
@@ -0,0 +1,193 @@
+//go:build windows
+
+package rdp
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/itzik715-cmd/kamatera-vdi/agent/internal/config"
+)
+
+// wslDistroName is the lightweight WSL2 distro used to run xfreerdp for
+// Linux desktop targets; it is registered once on first use.
+const wslDistroName = "kamvdi-client"
+
+// tarballManifest mirrors the signed descriptor the portal publishes
+// alongside the kamvdi-client root filesystem tarball.
+type tarballManifest struct {
+	Version string `json:"version"`
+	URL     string `json:"url"`
+	SHA256  string `json:"sha256"`
+	Sig     string `json:"sig"`
+}
+
+func (m tarballManifest) signedFields() []byte {
+	return []byte(fmt.Sprintf("%s|%s|%s", m.Version, m.URL, m.SHA256))
+}
+
+// launchWSLDesktop ensures the kamvdi-client WSL2 distro is registered,
+// then runs xfreerdp inside it (WSLg supplies the display) against the
+// local proxy endpoint.
+func launchWSLDesktop(params ConnectParams) (*exec.Cmd, error) {
+	if err := ensureWSLDistro(params.PortalURL); err != nil {
+		return nil, fmt.Errorf("cannot prepare WSL desktop environment: %w", err)
+	}
+
+	addr := fmt.Sprintf("/v:%s:%d", params.Host, params.Port)
+	cmd := exec.Command("wsl.exe", "-d", wslDistroName, "--", "xfreerdp", addr)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// ensureWSLDistro registers wslDistroName on first use, importing it from a
+// signed tarball published by the portal.
+func ensureWSLDistro(portalURL string) error {
+	if distroRegistered() {
+		return nil
+	}
+	if portalURL == "" {
+		return fmt.Errorf("no portal URL available to fetch the WSL distro image")
+	}
+
+	m, err := fetchTarballManifest(portalURL)
+	if err != nil {
+		return err
+	}
+
+	tarballPath, err := downloadVerifiedTarball(m)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tarballPath)
+
+	installDir, err := distroInstallDir()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("wsl.exe", "--import", wslDistroName, installDir, tarballPath)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("wsl --import failed: %w (%s)", err, out)
+	}
+
+	return nil
+}
+
+func distroRegistered() bool {
+	out, err := exec.Command("wsl.exe", "-l", "-q").Output()
+	if err != nil {
+		return false
+	}
+	// wsl -l -q prints UTF-16LE; for ASCII distro names every other byte is
+	// a zero, so drop the zeros before scanning for the name.
+	return strings.Contains(strings.ReplaceAll(string(out), "\x00", ""), wslDistroName)
+}
+
+func distroInstallDir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine config dir: %w", err)
+	}
+	dir := filepath.Join(base, "kamvdi", "wsl", wslDistroName)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("cannot create WSL install dir: %w", err)
+	}
+	return dir, nil
+}
+
+func fetchTarballManifest(portalURL string) (*tarballManifest, error) {
+	url := portalURL + "/downloads/kamvdi-client-wsl-manifest.json"
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("WSL manifest fetch failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("WSL manifest fetch returned HTTP %d", resp.StatusCode)
+	}
+
+	var m tarballManifest
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return nil, fmt.Errorf("WSL manifest: invalid response: %w", err)
+	}
+
+	if err := verifyTarballManifestSig(&m); err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+func verifyTarballManifestSig(m *tarballManifest) error {
+	pubKeyHex := config.WSLTarballPubKey
+	if pubKeyHex == "" {
+		return fmt.Errorf("WSL manifest verification: no public key embedded in this build")
+	}
+
+	pubKeyBytes, err := hex.DecodeString(pubKeyHex)
+	if err != nil || len(pubKeyBytes) != ed25519.PublicKeySize {
+		return fmt.Errorf("WSL manifest verification: invalid embedded public key")
+	}
+
+	sig, err := hex.DecodeString(m.Sig)
+	if err != nil {
+		return fmt.Errorf("WSL manifest verification: invalid signature encoding: %w", err)
+	}
+
+	if !ed25519.Verify(ed25519.PublicKey(pubKeyBytes), m.signedFields(), sig) {
+		return fmt.Errorf("WSL manifest verification: signature does not match")
+	}
+
+	return nil
+}
+
+// downloadVerifiedTarball streams m.URL to a temp file, verifying its
+// SHA-256 digest against the manifest before returning the path.
+func downloadVerifiedTarball(m *tarballManifest) (string, error) {
+	resp, err := http.Get(m.URL)
+	if err != nil {
+		return "", fmt.Errorf("WSL tarball download failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("WSL tarball download returned HTTP %d", resp.StatusCode)
+	}
+
+	tmp, err := os.CreateTemp("", "kamvdi-client-*.tar.gz")
+	if err != nil {
+		return "", fmt.Errorf("cannot create temp file: %w", err)
+	}
+
+	hasher := sha256.New()
+	if _, err := io.Copy(tmp, io.TeeReader(resp.Body, hasher)); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("WSL tarball download interrupted: %w", err)
+	}
+	tmp.Close()
+
+	digest := hex.EncodeToString(hasher.Sum(nil))
+	if digest != m.SHA256 {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("WSL tarball does not match manifest (sha256 mismatch)")
+	}
+
+	return tmp.Name(), nil
+}
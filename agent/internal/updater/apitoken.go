@@ -0,0 +1,138 @@
+package updater
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/itzik715-cmd/kamatera-vdi/agent/internal/keyring"
+)
+
+// apiTokenKeyringService is the keyring entry the local control-API token is
+// sealed under, so POST /api/update/apply can only be triggered by
+// something that knows this install's token, not by any web page that
+// happens to be open in the user's browser.
+const apiTokenKeyringService = "kamvdi-local-api-token"
+
+const apiTokenSize = 32
+
+// EnsureLocalAPIToken provisions the token required to call the agent's
+// local mutating control endpoints. On first run it generates a random
+// token and bootstraps it with the portal; subsequent calls are no-ops.
+// Called from --register.
+func EnsureLocalAPIToken(portalURL string) error {
+	if _, err := loadLocalAPIToken(); err == nil {
+		return nil // already provisioned
+	}
+
+	token := make([]byte, apiTokenSize)
+	if _, err := rand.Read(token); err != nil {
+		return fmt.Errorf("cannot generate local API token: %w", err)
+	}
+
+	if portalURL != "" {
+		if err := bootstrapAPIToken(portalURL, token); err != nil {
+			return fmt.Errorf("cannot bootstrap local API token with portal: %w", err)
+		}
+	}
+
+	sealed, err := keyring.Seal(apiTokenKeyringService, token)
+	if err != nil {
+		return fmt.Errorf("cannot seal local API token in platform keyring: %w", err)
+	}
+
+	return saveSealedAPIToken(sealed)
+}
+
+// RemoveLocalAPIToken deletes the local API token. Called from --unregister.
+func RemoveLocalAPIToken() error {
+	if err := keyring.Remove(apiTokenKeyringService); err != nil {
+		return err
+	}
+	return removeSealedAPITokenFile()
+}
+
+func localAPITokenPath() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine config dir: %w", err)
+	}
+	dir := filepath.Join(base, "kamvdi")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("cannot create config dir: %w", err)
+	}
+	return filepath.Join(dir, "local-api-token.sealed"), nil
+}
+
+func loadLocalAPIToken() ([]byte, error) {
+	path, err := localAPITokenPath()
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return keyring.Open(apiTokenKeyringService, sealed)
+}
+
+func saveSealedAPIToken(sealed []byte) error {
+	path, err := localAPITokenPath()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, sealed, 0o600)
+}
+
+func removeSealedAPITokenFile() error {
+	path, err := localAPITokenPath()
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// bootstrapAPIToken performs a one-time POST of the freshly generated token
+// to the portal, so it can later authenticate calls to this install's local
+// control endpoints.
+func bootstrapAPIToken(portalURL string, token []byte) error {
+	url := portalURL + "/api/agent/bootstrap-api-token"
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(url, "application/octet-stream", bytes.NewReader(token))
+	if err != nil {
+		return fmt.Errorf("bootstrap request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bootstrap request returned HTTP %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// checkLocalAPIToken reports whether r carries this install's local API
+// token in its X-KamVDI-Token header.
+func checkLocalAPIToken(r *http.Request) bool {
+	want, err := loadLocalAPIToken()
+	if err != nil {
+		return false
+	}
+
+	got := []byte(r.Header.Get("X-KamVDI-Token"))
+	return len(got) == len(want) && subtle.ConstantTimeCompare(got, want) == 1
+}
@@ -0,0 +1,154 @@
+package updater
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/itzik715-cmd/kamatera-vdi/agent/internal/config"
+)
+
+// Apply installs info onto the running agent: a matching delta patch
+// against the currently running binary if one is available and verifies
+// cleanly, otherwise a full download. Either way the reconstructed file is
+// verified against info.SHA256/info.Signature before the atomic swap, and
+// on success the agent re-execs into the new binary.
+func Apply(ctx context.Context, info versionInfo) error {
+	if info.DownloadURL == "" && len(info.Patches) == 0 {
+		return fmt.Errorf("update %s has no download URL or patches", info.Version)
+	}
+	if info.SHA256 == "" || info.Signature == "" {
+		return fmt.Errorf("update %s is missing signature metadata, refusing to apply", info.Version)
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("cannot determine executable path: %w", err)
+	}
+
+	if tmpPath, err := applyPatch(ctx, exePath, info); err == nil {
+		defer os.Remove(tmpPath)
+		return installAndReexec(exePath, tmpPath)
+	} else if err != errNoMatchingPatch {
+		log.Printf("Delta update for %s failed, falling back to full download: %v", info.Version, err)
+	}
+
+	if info.DownloadURL == "" {
+		return fmt.Errorf("update %s has no full download URL and no usable patch", info.Version)
+	}
+
+	tmpPath := exePath + ".update"
+	size, digest, err := downloadTo(ctx, info.DownloadURL, tmpPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+
+	if info.Size != 0 && size != info.Size {
+		return fmt.Errorf("downloaded update size %d does not match manifest size %d", size, info.Size)
+	}
+	if digest != info.SHA256 {
+		return fmt.Errorf("downloaded update does not match manifest sha256")
+	}
+	if err := verifySignature(digest, info.Signature); err != nil {
+		return err
+	}
+
+	return installAndReexec(exePath, tmpPath)
+}
+
+// installAndReexec finalizes tmpPath as the agent's new executable: makes
+// it executable, atomically swaps it in for exePath, and re-execs into it.
+func installAndReexec(exePath, tmpPath string) error {
+	if err := os.Chmod(tmpPath, 0o755); err != nil {
+		return fmt.Errorf("cannot set executable permissions: %w", err)
+	}
+
+	if err := atomicReplace(exePath, tmpPath); err != nil {
+		return fmt.Errorf("cannot install update: %w", err)
+	}
+
+	return reexec(exePath)
+}
+
+// downloadTo streams url to path, returning the downloaded size and its
+// SHA-256 digest.
+func downloadTo(ctx context.Context, url, path string) (int64, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, "", fmt.Errorf("cannot build update request: %w", err)
+	}
+
+	client := &http.Client{Timeout: 2 * time.Minute}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("update download failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, "", fmt.Errorf("update download returned HTTP %d", resp.StatusCode)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, "", fmt.Errorf("cannot create temp file: %w", err)
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	size, err := io.Copy(f, io.TeeReader(resp.Body, hasher))
+	if err != nil {
+		return 0, "", fmt.Errorf("update download interrupted: %w", err)
+	}
+
+	return size, hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// sha256File returns the hex-encoded SHA-256 digest of the file at path.
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot open %s for verification: %w", path, err)
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return "", fmt.Errorf("cannot hash %s: %w", path, err)
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// verifySignature checks the detached signature over the hex-encoded
+// SHA-256 digest against the public key embedded in config at build time.
+func verifySignature(digestHex, sigHex string) error {
+	pubKeyHex := config.UpdaterPubKey
+	if pubKeyHex == "" {
+		return fmt.Errorf("update verification: no public key embedded in this build")
+	}
+
+	pubKeyBytes, err := hex.DecodeString(pubKeyHex)
+	if err != nil || len(pubKeyBytes) != ed25519.PublicKeySize {
+		return fmt.Errorf("update verification: invalid embedded public key")
+	}
+
+	sig, err := hex.DecodeString(sigHex)
+	if err != nil {
+		return fmt.Errorf("update verification: invalid signature encoding: %w", err)
+	}
+
+	if !ed25519.Verify(ed25519.PublicKey(pubKeyBytes), []byte(digestHex), sig) {
+		return fmt.Errorf("update verification: signature does not match")
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/itzik715-cmd/kamatera-vdi/agent/internal/config"
+)
+
+// UpdateOptions configures a `cwmvdi update` invocation.
+type UpdateOptions struct {
+	// Channel overrides the install's configured release channel for this
+	// run only ("" keeps the configured channel).
+	Channel string
+	// Version pins to one specific published build, upgrade or downgrade,
+	// bypassing channel resolution entirely ("" follows the channel).
+	Version string
+	// Force bypasses the "already up to date" short-circuit.
+	Force bool
+	// DryRun reports what would be installed without downloading or
+	// applying anything.
+	DryRun bool
+}
+
+// RunUpdate implements `cwmvdi update`: resolve the version to install
+// (from opts.Version, or the configured/overridden channel), and unless
+// DryRun, download, verify and install it. It blocks until the update is
+// applied or an error occurs.
+func RunUpdate(ctx context.Context, portalURL string, opts UpdateOptions) error {
+	source := sourceFor(portalURL)
+
+	var (
+		info versionInfo
+		err  error
+	)
+	switch {
+	case opts.Version != "":
+		info, err = source.FetchPinned(ctx, opts.Version)
+	case opts.Channel != "":
+		info, err = source.Fetch(ctx, opts.Channel)
+	default:
+		info, err = source.Fetch(ctx, config.UpdateChannel())
+	}
+	if err != nil {
+		return fmt.Errorf("update check failed: %w", err)
+	}
+
+	if info.Version == config.AgentVersion && !opts.Force {
+		fmt.Printf("Already up to date (%s)\n", config.AgentVersion)
+		return nil
+	}
+
+	if opts.DryRun {
+		fmt.Printf("Would update from %s to %s (%s)\n", config.AgentVersion, info.Version, info.DownloadURL)
+		return nil
+	}
+
+	fmt.Printf("Updating from %s to %s...\n", config.AgentVersion, info.Version)
+	if err := Apply(ctx, info); err != nil {
+		return fmt.Errorf("update failed: %w", err)
+	}
+
+	return nil // unreachable on success: Apply re-execs into the new binary
+}
@@ -0,0 +1,58 @@
+package updater
+
+import "sync"
+
+// Event is published whenever the agent's update/quarantine State changes,
+// so subscribers don't each have to poll or re-derive it.
+type Event struct {
+	State State
+}
+
+// subscribers holds every channel currently registered via Subscribe.
+var subscribers = struct {
+	sync.Mutex
+	chans []chan Event
+}{}
+
+// Subscribe registers a new listener for update state changes. The returned
+// channel is buffered so a slow consumer doesn't block publishing; callers
+// that no longer want updates should call Unsubscribe with the same channel.
+func Subscribe() <-chan Event {
+	ch := make(chan Event, 4)
+
+	subscribers.Lock()
+	subscribers.chans = append(subscribers.chans, ch)
+	subscribers.Unlock()
+
+	return ch
+}
+
+// Unsubscribe deregisters a channel previously returned by Subscribe and
+// closes it.
+func Unsubscribe(ch <-chan Event) {
+	subscribers.Lock()
+	defer subscribers.Unlock()
+
+	for i, c := range subscribers.chans {
+		if c == ch {
+			subscribers.chans = append(subscribers.chans[:i], subscribers.chans[i+1:]...)
+			close(c)
+			return
+		}
+	}
+}
+
+// publish notifies every current subscriber of the latest state. Subscribers
+// that aren't keeping up have the event dropped rather than blocking the
+// publisher.
+func publish(s State) {
+	subscribers.Lock()
+	defer subscribers.Unlock()
+
+	for _, ch := range subscribers.chans {
+		select {
+		case ch <- Event{State: s}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package updater
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/itzik715-cmd/kamatera-vdi/agent/internal/config"
+)
+
+type statusResponse struct {
+	Current     string `json:"current"`
+	Latest      string `json:"latest"`
+	Min         string `json:"min"`
+	Channel     string `json:"channel"`
+	State       string `json:"state"`
+	DownloadURL string `json:"download_url,omitempty"`
+	CheckedAt   string `json:"checked_at,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// StatusHandler serves GET /api/update/status, mounted on the agent's local
+// health server. It lets the tray and portal UI render an "update
+// available" banner off the agent's own last check instead of each
+// re-polling GitHub or the portal themselves.
+func StatusHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "*")
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	s := Snapshot()
+	resp := statusResponse{
+		Current:     s.Current,
+		Latest:      s.Latest,
+		Min:         s.Minimum,
+		Channel:     s.Channel,
+		State:       s.statusString(),
+		DownloadURL: s.DownloadURL,
+		Error:       s.Error,
+	}
+	if !s.CheckedAt.IsZero() {
+		resp.CheckedAt = s.CheckedAt.Format(time.RFC3339)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// ApplyHandler serves POST /api/update/apply, triggering an immediate
+// download+verify+swap of the resolved version. The swap runs in the
+// background and the agent re-execs once it completes, so this only
+// acknowledges that the attempt started.
+//
+// Unlike the read-only /health and /api/update/status endpoints, this one
+// mutates and restarts the agent, so a wide-open CORS policy alone isn't
+// enough: any site the user has open could otherwise trigger it with a
+// bare cross-origin POST. The caller must also present this install's local
+// API token (provisioned during --register) in X-KamVDI-Token, which forces
+// a CORS preflight and which an arbitrary web page has no way to know.
+func ApplyHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "X-KamVDI-Token")
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if !checkLocalAPIToken(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "missing or invalid X-KamVDI-Token"})
+		return
+	}
+
+	portalURL := config.LoadPortalURL()
+	go func() {
+		if err := RunUpdate(context.Background(), portalURL, UpdateOptions{Force: true}); err != nil {
+			log.Printf("On-demand update failed: %v", err)
+			setState(func(s *State) { s.Error = err.Error() })
+		}
+	}()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"status": "update started"})
+}
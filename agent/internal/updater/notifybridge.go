@@ -0,0 +1,49 @@
+package updater
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/itzik715-cmd/kamatera-vdi/agent/internal/notify"
+)
+
+var bridgeOnce sync.Once
+
+// startNotifyBridge wires the desktop toast notifier up as an ordinary
+// Subscribe-r of update state changes, so it has no special access to
+// state transitions that the HTTP status endpoint and tray don't also see.
+func startNotifyBridge() {
+	bridgeOnce.Do(func() {
+		go notifyOnChange(Subscribe())
+	})
+}
+
+// notifyOnChange shows a toast the first time a quarantine or an available
+// update appears, rather than on every periodic check.
+func notifyOnChange(events <-chan Event) {
+	var lastQuarantineReason, lastLatestNotified string
+
+	for ev := range events {
+		s := ev.State
+
+		if s.Quarantined {
+			if s.QuarantineReason != lastQuarantineReason {
+				notify.Show("KamVDI Agent Blocked", s.QuarantineReason)
+				lastQuarantineReason = s.QuarantineReason
+			}
+			continue
+		}
+		lastQuarantineReason = ""
+
+		if s.Latest == "" || s.Latest == s.Current || s.Latest == lastLatestNotified {
+			continue
+		}
+
+		msg := fmt.Sprintf("A new version (%s) is available. You are running %s.", s.Latest, s.Current)
+		if s.DownloadURL != "" {
+			msg += fmt.Sprintf("\nDownload: %s", s.DownloadURL)
+		}
+		notify.Show("KamVDI Update", msg)
+		lastLatestNotified = s.Latest
+	}
+}
@@ -0,0 +1,84 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/gabstv/go-bsdiff/pkg/bspatch"
+	"github.com/itzik715-cmd/kamatera-vdi/agent/internal/config"
+)
+
+// PatchEntry describes a binary delta that reconstructs the full update
+// artifact when applied to a specific prior version with bspatch.
+type PatchEntry struct {
+	FromVersion string `json:"from_version"`
+	URL         string `json:"url"`
+	SHA256      string `json:"sha256"`
+	Signature   string `json:"signature"`
+}
+
+// errNoMatchingPatch signals that info has no patch for the running
+// version, so Apply should go straight to the full download.
+var errNoMatchingPatch = errors.New("no patch available for the running version")
+
+// applyPatch downloads and verifies the delta patch matching the running
+// agent version (if any), reconstructs the new binary against exePath, and
+// verifies the result against info's full-binary SHA256/Signature. On
+// success it returns the path to the verified reconstructed binary; the
+// caller is responsible for removing it. Returns errNoMatchingPatch if
+// info.Patches has nothing usable for this install.
+func applyPatch(ctx context.Context, exePath string, info versionInfo) (string, error) {
+	entry, ok := findPatch(info.Patches, config.AgentVersion)
+	if !ok {
+		return "", errNoMatchingPatch
+	}
+
+	patchPath := exePath + ".patch"
+	_, digest, err := downloadTo(ctx, entry.URL, patchPath)
+	if err != nil {
+		return "", fmt.Errorf("patch download failed: %w", err)
+	}
+	defer os.Remove(patchPath)
+
+	if digest != entry.SHA256 {
+		return "", fmt.Errorf("downloaded patch does not match manifest sha256")
+	}
+	if err := verifySignature(digest, entry.Signature); err != nil {
+		return "", fmt.Errorf("patch signature invalid: %w", err)
+	}
+
+	reconstructedPath := exePath + ".update"
+	if err := bspatch.File(exePath, reconstructedPath, patchPath); err != nil {
+		os.Remove(reconstructedPath)
+		return "", fmt.Errorf("bspatch failed: %w", err)
+	}
+
+	reconstructedDigest, err := sha256File(reconstructedPath)
+	if err != nil {
+		os.Remove(reconstructedPath)
+		return "", err
+	}
+	if reconstructedDigest != info.SHA256 {
+		os.Remove(reconstructedPath)
+		return "", fmt.Errorf("reconstructed binary does not match manifest sha256")
+	}
+	if err := verifySignature(reconstructedDigest, info.Signature); err != nil {
+		os.Remove(reconstructedPath)
+		return "", err
+	}
+
+	return reconstructedPath, nil
+}
+
+// findPatch returns the patch entry that reconstructs the update from
+// fromVersion, if info.Patches has one.
+func findPatch(patches []PatchEntry, fromVersion string) (PatchEntry, bool) {
+	for _, p := range patches {
+		if p.FromVersion == fromVersion {
+			return p, true
+		}
+	}
+	return PatchEntry{}, false
+}
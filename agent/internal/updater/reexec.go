@@ -0,0 +1,21 @@
+package updater
+
+import "os"
+
+// reexecArgs returns the argv to re-exec into, with --force and --dry-run
+// stripped. Apply always re-execs after a successful install, and the
+// freshly installed binary re-enters main() with that argv: if the
+// original invocation was `cwmvdi update --force`, keeping --force would
+// make the new process bypass the already-up-to-date short-circuit (it's
+// now running info.Version) and call Apply again, looping forever.
+func reexecArgs() []string {
+	args := make([]string, 0, len(os.Args))
+	args = append(args, os.Args[0])
+	for _, a := range os.Args[1:] {
+		if a == "--force" || a == "--dry-run" {
+			continue
+		}
+		args = append(args, a)
+	}
+	return args
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package updater
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// reexec replaces the current process image with the freshly installed
+// binary at exePath.
+func reexec(exePath string) error {
+	if err := syscall.Exec(exePath, reexecArgs(), os.Environ()); err != nil {
+		return fmt.Errorf("cannot re-exec updated agent: %w", err)
+	}
+	return nil // unreachable: syscall.Exec only returns on error
+}
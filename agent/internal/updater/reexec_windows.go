@@ -0,0 +1,22 @@
+//go:build windows
+
+package updater
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// reexec starts the freshly installed binary as a new process and exits
+// this one; Windows has no syscall.Exec equivalent.
+func reexec(exePath string) error {
+	cmd := exec.Command(exePath, reexecArgs()[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("cannot start updated agent: %w", err)
+	}
+	os.Exit(0)
+	return nil // unreachable
+}
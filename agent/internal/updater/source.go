@@ -0,0 +1,88 @@
+package updater
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/itzik715-cmd/kamatera-vdi/agent/internal/config"
+)
+
+// Source fetches the published version descriptor for a release channel, or
+// for one specific pinned version. Implementations back onto different
+// publishing backends (the KamVDI portal, GitHub Releases, ...).
+type Source interface {
+	Fetch(ctx context.Context, channel string) (versionInfo, error)
+	FetchPinned(ctx context.Context, version string) (versionInfo, error)
+}
+
+// githubSource is shared across checks so its ETag-cached release list
+// carries over from one check to the next.
+var githubSource = NewGitHubReleasesSource()
+
+// sourceFor picks the Source an install should use: the portal if one is
+// configured, otherwise GitHub Releases directly.
+func sourceFor(portalURL string) Source {
+	if portalURL != "" {
+		return NewPortalSource(portalURL)
+	}
+	return githubSource
+}
+
+// portalSource is the default Source, backed by the KamVDI portal's static
+// per-channel version.json.
+type portalSource struct {
+	client    *http.Client
+	portalURL string
+}
+
+// NewPortalSource returns a Source that reads version.json from the given
+// portal's downloads directory.
+func NewPortalSource(portalURL string) Source {
+	return &portalSource{portalURL: portalURL, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *portalSource) Fetch(ctx context.Context, channel string) (versionInfo, error) {
+	if s.portalURL == "" {
+		return versionInfo{}, fmt.Errorf("no portal URL configured")
+	}
+	if channel == "" {
+		channel = config.StableChannel
+	}
+	url := fmt.Sprintf("%s/downloads/%s/version.json", s.portalURL, channel)
+	return s.fetchJSON(ctx, url)
+}
+
+func (s *portalSource) FetchPinned(ctx context.Context, version string) (versionInfo, error) {
+	if s.portalURL == "" {
+		return versionInfo{}, fmt.Errorf("no portal URL configured")
+	}
+	url := fmt.Sprintf("%s/downloads/%s/version.json", s.portalURL, version)
+	return s.fetchJSON(ctx, url)
+}
+
+func (s *portalSource) fetchJSON(ctx context.Context, url string) (versionInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return versionInfo{}, fmt.Errorf("cannot build version check request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return versionInfo{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return versionInfo{}, fmt.Errorf("version check returned HTTP %d", resp.StatusCode)
+	}
+
+	var info versionInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return versionInfo{}, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return info, nil
+}
@@ -0,0 +1,233 @@
+package updater
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/itzik715-cmd/kamatera-vdi/agent/internal/config"
+)
+
+// githubOwner/githubRepo identify where KamVDI agent releases are published.
+const (
+	githubOwner = "itzik715-cmd"
+	githubRepo  = "cwmvdi"
+)
+
+type githubRelease struct {
+	TagName    string        `json:"tag_name"`
+	Prerelease bool          `json:"prerelease"`
+	Draft      bool          `json:"draft"`
+	Assets     []githubAsset `json:"assets"`
+}
+
+type githubAsset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+	Size               int64  `json:"size"`
+}
+
+// GitHubReleasesSource reads published releases directly from GitHub, for
+// installs that aren't behind a KamVDI portal. It caches the release list
+// behind an ETag so repeated checks don't count against the unauthenticated
+// rate limit unless something actually changed.
+type GitHubReleasesSource struct {
+	client *http.Client
+
+	mu       sync.Mutex
+	etag     string
+	releases []githubRelease
+}
+
+// NewGitHubReleasesSource returns a Source backed by the public GitHub
+// Releases API.
+func NewGitHubReleasesSource() *GitHubReleasesSource {
+	return &GitHubReleasesSource{client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *GitHubReleasesSource) Fetch(ctx context.Context, channel string) (versionInfo, error) {
+	releases, err := s.listReleases(ctx)
+	if err != nil {
+		return versionInfo{}, err
+	}
+
+	release, ok := newestForChannel(releases, channel)
+	if !ok {
+		return versionInfo{}, fmt.Errorf("no published release found for channel %q", channel)
+	}
+
+	return s.infoFromRelease(ctx, release)
+}
+
+func (s *GitHubReleasesSource) FetchPinned(ctx context.Context, version string) (versionInfo, error) {
+	releases, err := s.listReleases(ctx)
+	if err != nil {
+		return versionInfo{}, err
+	}
+
+	want := strings.TrimPrefix(version, "v")
+	for _, r := range releases {
+		if strings.TrimPrefix(r.TagName, "v") == want {
+			return s.infoFromRelease(ctx, r)
+		}
+	}
+
+	return versionInfo{}, fmt.Errorf("no published release found for version %q", version)
+}
+
+func (s *GitHubReleasesSource) infoFromRelease(ctx context.Context, release githubRelease) (versionInfo, error) {
+	asset, ok := assetForPlatform(release.Assets)
+	if !ok {
+		return versionInfo{}, fmt.Errorf("release %s has no asset for %s/%s", release.TagName, runtime.GOOS, platformArch())
+	}
+
+	info := versionInfo{
+		Version:     strings.TrimPrefix(release.TagName, "v"),
+		DownloadURL: asset.BrowserDownloadURL,
+		Size:        asset.Size,
+	}
+
+	if digest, err := s.fetchSidecar(ctx, asset.BrowserDownloadURL+".sha256"); err == nil {
+		if fields := strings.Fields(digest); len(fields) > 0 {
+			info.SHA256 = fields[0]
+		}
+	}
+	if sig, err := s.fetchSidecar(ctx, asset.BrowserDownloadURL+".sig"); err == nil {
+		info.Signature = strings.TrimSpace(sig)
+	}
+
+	return info, nil
+}
+
+func (s *GitHubReleasesSource) listReleases(ctx context.Context) ([]githubRelease, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases", githubOwner, githubRepo)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot build GitHub releases request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	s.mu.Lock()
+	etag := s.etag
+	s.mu.Unlock()
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub releases request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if remaining := resp.Header.Get("X-RateLimit-Remaining"); remaining == "0" {
+		return nil, fmt.Errorf("GitHub API rate limit exhausted, try again later")
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.releases == nil {
+			return nil, fmt.Errorf("GitHub returned 304 but no releases are cached yet")
+		}
+		return s.releases, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub releases request returned HTTP %d", resp.StatusCode)
+	}
+
+	var releases []githubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("invalid GitHub releases response: %w", err)
+	}
+
+	s.mu.Lock()
+	s.etag = resp.Header.Get("ETag")
+	s.releases = releases
+	s.mu.Unlock()
+
+	return releases, nil
+}
+
+func (s *GitHubReleasesSource) fetchSidecar(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("sidecar request returned HTTP %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// channelSuffix returns the release tag suffix that identifies channel;
+// stable releases carry no suffix.
+func channelSuffix(channel string) string {
+	switch channel {
+	case config.BetaChannel:
+		return "-beta"
+	case config.NightlyChannel:
+		return "-nightly"
+	default:
+		return ""
+	}
+}
+
+// newestForChannel returns the newest non-draft release whose tag matches
+// channel, relying on the GitHub API's newest-first ordering.
+func newestForChannel(releases []githubRelease, channel string) (githubRelease, bool) {
+	suffix := channelSuffix(channel)
+	for _, r := range releases {
+		if r.Draft {
+			continue
+		}
+		if suffix == "" {
+			if !strings.Contains(r.TagName, "-beta") && !strings.Contains(r.TagName, "-nightly") {
+				return r, true
+			}
+			continue
+		}
+		if strings.HasSuffix(r.TagName, suffix) {
+			return r, true
+		}
+	}
+	return githubRelease{}, false
+}
+
+// assetForPlatform picks the release asset matching the running GOOS/GOARCH,
+// accounting for ARM revision on Linux the way AdGuard Home's updater does.
+func assetForPlatform(assets []githubAsset) (githubAsset, bool) {
+	want := fmt.Sprintf("%s-%s", runtime.GOOS, platformArch())
+	for _, a := range assets {
+		if strings.Contains(a.Name, want) {
+			return a, true
+		}
+	}
+	return githubAsset{}, false
+}
+
+func platformArch() string {
+	if runtime.GOARCH == "arm" {
+		return "armv" + config.ArmVersion
+	}
+	return runtime.GOARCH
+}
@@ -0,0 +1,125 @@
+package updater
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/itzik715-cmd/kamatera-vdi/agent/internal/config"
+)
+
+// State describes the agent's view of its own version standing, for
+// subsystems (tray UI, portal UI, session manager) that need to react to it
+// without reaching into updater internals.
+type State struct {
+	Current          string
+	Latest           string
+	Minimum          string
+	Channel          string
+	DownloadURL      string
+	CheckedAt        time.Time
+	Quarantined      bool
+	QuarantineReason string
+	Error            string
+}
+
+// statusString summarizes State as one of a small set of machine-readable
+// values for the /api/update/status response.
+func (s State) statusString() string {
+	switch {
+	case s.Error != "":
+		return "error"
+	case s.Quarantined:
+		return "quarantined"
+	case s.Latest != "" && s.Latest != s.Current:
+		return "update-available"
+	default:
+		return "up-to-date"
+	}
+}
+
+var current = struct {
+	sync.Mutex
+	s State
+}{}
+
+// Snapshot returns a copy of the agent's current update/quarantine status.
+func Snapshot() State {
+	current.Lock()
+	defer current.Unlock()
+	return current.s
+}
+
+// setState applies fn to the shared state and publishes the result to every
+// Subscribe-r, so notify and anything else watching see the same change.
+func setState(fn func(s *State)) {
+	current.Lock()
+	fn(&current.s)
+	snapshot := current.s
+	current.Unlock()
+
+	publish(snapshot)
+}
+
+// updateState refreshes the Current/Latest/Minimum/Channel fields from the
+// latest check, clearing any stale error.
+func updateState(info versionInfo, portalURL string) {
+	setState(func(s *State) {
+		s.Current = config.AgentVersion
+		s.Latest = info.Version
+		s.Minimum = info.MinVersion
+		s.Channel = config.UpdateChannel()
+		s.DownloadURL = info.DownloadURL
+		s.CheckedAt = time.Now()
+		s.Error = ""
+	})
+}
+
+// evaluateQuarantine checks info.MinVersion against the running agent
+// version and updates the shared quarantine state accordingly. It returns
+// whether the agent is quarantined and, if so, why.
+func evaluateQuarantine(info versionInfo) (bool, string) {
+	if info.MinVersion == "" {
+		setState(func(s *State) {
+			s.Quarantined = false
+			s.QuarantineReason = ""
+		})
+		return false, ""
+	}
+
+	below, err := belowMinimum(config.AgentVersion, info.MinVersion)
+	if err != nil {
+		// Can't parse the versions involved; fail open rather than block
+		// sessions on a malformed portal response.
+		setState(func(s *State) {
+			s.Quarantined = false
+			s.QuarantineReason = ""
+			s.Error = err.Error()
+		})
+		return false, ""
+	}
+
+	if !below {
+		setState(func(s *State) {
+			s.Quarantined = false
+			s.QuarantineReason = ""
+		})
+		return false, ""
+	}
+
+	reason := fmt.Sprintf("KamVDI Agent %s is below the minimum required version %s. New sessions are blocked until it updates.",
+		config.AgentVersion, info.MinVersion)
+	setState(func(s *State) {
+		s.Quarantined = true
+		s.QuarantineReason = reason
+	})
+	return true, reason
+}
+
+// Quarantined reports whether the agent is currently refusing new VDI
+// sessions because it's below the portal's minimum required version.
+func Quarantined() (bool, string) {
+	current.Lock()
+	defer current.Unlock()
+	return current.s.Quarantined, current.s.QuarantineReason
+}
@@ -0,0 +1,12 @@
+//go:build !windows
+
+package updater
+
+import "os"
+
+// atomicReplace swaps newPath into place at exePath. On Unix this is a
+// single atomic rename — the running process keeps its open file
+// descriptor to the old inode until it re-execs.
+func atomicReplace(exePath, newPath string) error {
+	return os.Rename(newPath, exePath)
+}
@@ -0,0 +1,51 @@
+//go:build windows
+
+package updater
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// atomicReplace swaps newPath into place at exePath using the classic
+// Windows "rename running exe to .old, drop the new one in place" dance —
+// Windows won't let you overwrite a file that's mapped into a running
+// process directly. The .old copy is deleted at the next reboot, or at the
+// start of the next update, whichever comes first.
+func atomicReplace(exePath, newPath string) error {
+	oldPath := exePath + ".old"
+	os.Remove(oldPath) // best-effort cleanup from a previous update
+
+	exePathPtr, err := windows.UTF16PtrFromString(exePath)
+	if err != nil {
+		return err
+	}
+	oldPathPtr, err := windows.UTF16PtrFromString(oldPath)
+	if err != nil {
+		return err
+	}
+	newPathPtr, err := windows.UTF16PtrFromString(newPath)
+	if err != nil {
+		return err
+	}
+
+	if err := windows.MoveFileEx(exePathPtr, oldPathPtr, windows.MOVEFILE_REPLACE_EXISTING); err != nil {
+		return fmt.Errorf("cannot move running executable aside: %w", err)
+	}
+
+	if err := windows.MoveFileEx(newPathPtr, exePathPtr,
+		windows.MOVEFILE_REPLACE_EXISTING|windows.MOVEFILE_WRITE_THROUGH); err != nil {
+		return fmt.Errorf("cannot install new executable: %w", err)
+	}
+
+	// Best-effort: the old binary is still mapped into this process, so it
+	// can only be deleted once we've exited. Ask Windows to do it on the
+	// next reboot if the immediate delete fails.
+	if err := os.Remove(oldPath); err != nil {
+		windows.MoveFileEx(oldPathPtr, nil, windows.MOVEFILE_DELAY_UNTIL_REBOOT)
+	}
+
+	return nil
+}
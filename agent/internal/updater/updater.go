@@ -1,10 +1,9 @@
 package updater
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"log"
-	"net/http"
 	"time"
 
 	"github.com/itzik715-cmd/kamatera-vdi/agent/internal/config"
@@ -12,9 +11,13 @@ import (
 )
 
 type versionInfo struct {
-	Version    string `json:"version"`
-	MinVersion string `json:"min_version"`
-	DownloadURL string `json:"download_url,omitempty"`
+	Version     string       `json:"version"`
+	MinVersion  string       `json:"min_version"`
+	DownloadURL string       `json:"download_url,omitempty"`
+	SHA256      string       `json:"sha256,omitempty"`
+	Signature   string       `json:"signature,omitempty"`
+	Size        int64        `json:"size,omitempty"`
+	Patches     []PatchEntry `json:"patches,omitempty"`
 }
 
 // StartBackgroundCheck periodically checks for agent updates.
@@ -24,6 +27,8 @@ func StartBackgroundCheck(portalURL string) {
 		return
 	}
 
+	startNotifyBridge()
+
 	go func() {
 		// Wait a bit before first check
 		time.Sleep(30 * time.Second)
@@ -40,32 +45,50 @@ func StartBackgroundCheck(portalURL string) {
 }
 
 func check(portalURL string) {
-	url := fmt.Sprintf("%s/downloads/version.json", portalURL)
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(url)
+	info, err := resolveVersionInfo(context.Background(), portalURL)
 	if err != nil {
 		log.Printf("Update check failed: %v", err)
+		setState(func(s *State) { s.Error = err.Error() })
 		return
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	updateState(info, portalURL)
+
+	quarantined, reason := evaluateQuarantine(info)
+	if quarantined {
+		log.Printf("Agent quarantined: %s", reason)
+		if config.AutoApply() {
+			log.Printf("AutoApply enabled, applying update to %s immediately to clear quarantine", info.Version)
+			if err := Apply(context.Background(), info); err != nil {
+				log.Printf("Auto-update failed: %v", err)
+				notify.Show("KamVDI Update Failed", fmt.Sprintf("Could not apply update %s: %v", info.Version, err))
+			}
+		}
 		return
 	}
 
-	var info versionInfo
-	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
-		log.Printf("Update check: invalid response: %v", err)
+	if info.Version == config.AgentVersion {
 		return
 	}
 
-	if info.Version != config.AgentVersion {
-		msg := fmt.Sprintf("A new version (%s) is available. You are running %s.",
-			info.Version, config.AgentVersion)
-		if info.DownloadURL != "" {
-			msg += fmt.Sprintf("\nDownload: %s", info.DownloadURL)
+	if config.AutoApply() {
+		log.Printf("AutoApply enabled, applying update to %s immediately", info.Version)
+		if err := Apply(context.Background(), info); err != nil {
+			log.Printf("Auto-update failed: %v", err)
+			notify.Show("KamVDI Update Failed", fmt.Sprintf("Could not apply update %s: %v", info.Version, err))
 		}
-		notify.Show("KamVDI Update", msg)
 	}
 }
+
+// resolveVersionInfo fetches the version descriptor for this install's
+// configured channel, from the portal if one is registered or GitHub
+// Releases otherwise, and applies any pinned version override.
+func resolveVersionInfo(ctx context.Context, portalURL string) (versionInfo, error) {
+	source := sourceFor(portalURL)
+
+	if pinned := config.PinnedVersion(); pinned != "" {
+		return source.FetchPinned(ctx, pinned)
+	}
+
+	return source.Fetch(ctx, config.UpdateChannel())
+}
@@ -0,0 +1,22 @@
+package updater
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-version"
+)
+
+// belowMinimum reports whether current is a strictly older version than min,
+// using full semver precedence (pre-release suffixes included, per SemVer
+// §11 — e.g. 1.4.0-rc1 sorts before 1.4.0).
+func belowMinimum(current, min string) (bool, error) {
+	curVer, err := version.NewVersion(current)
+	if err != nil {
+		return false, fmt.Errorf("cannot parse current version %q: %w", current, err)
+	}
+	minVer, err := version.NewVersion(min)
+	if err != nil {
+		return false, fmt.Errorf("cannot parse minimum version %q: %w", min, err)
+	}
+	return curVer.LessThan(minVer), nil
+}
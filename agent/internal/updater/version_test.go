@@ -0,0 +1,35 @@
+package updater
+
+import "testing"
+
+func TestBelowMinimum(t *testing.T) {
+	cases := []struct {
+		current, min string
+		want         bool
+	}{
+		{"1.4.0", "1.4.0", false},
+		{"1.3.9", "1.4.0", true},
+		{"1.5.0", "1.4.0", false},
+		{"1.4.0-rc1", "1.4.0", true},
+		{"1.4.0", "1.4.0-rc1", false},
+		{"1.4.0-rc1", "1.4.0-rc2", true},
+		{"1.4.0-rc2", "1.4.0-rc1", false},
+	}
+
+	for _, c := range cases {
+		got, err := belowMinimum(c.current, c.min)
+		if err != nil {
+			t.Errorf("belowMinimum(%q, %q) returned error: %v", c.current, c.min, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("belowMinimum(%q, %q) = %v, want %v", c.current, c.min, got, c.want)
+		}
+	}
+}
+
+func TestBelowMinimumInvalidVersion(t *testing.T) {
+	if _, err := belowMinimum("not-a-version", "1.0.0"); err == nil {
+		t.Error("expected an error for an unparsable current version, got nil")
+	}
+}
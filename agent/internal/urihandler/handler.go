@@ -1,13 +1,21 @@
 package urihandler
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"fmt"
 	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/itzik715-cmd/kamatera-vdi/agent/internal/boundary"
 	"github.com/itzik715-cmd/kamatera-vdi/agent/internal/heartbeat"
 	"github.com/itzik715-cmd/kamatera-vdi/agent/internal/notify"
 	"github.com/itzik715-cmd/kamatera-vdi/agent/internal/rdp"
+	"github.com/itzik715-cmd/kamatera-vdi/agent/internal/updater"
 )
 
 // ConnectParams holds the parsed kamvdi:// URI parameters.
@@ -17,9 +25,15 @@ type ConnectParams struct {
 	SessionID   string
 	DesktopName string
 	PortalURL   string
+	Protocol    string
 }
 
-// ParseKamVDIUri parses a kamvdi://connect?token=xxx&worker=yyy&session=zzz URI.
+// ParseKamVDIUri parses and authenticates a
+// kamvdi://connect?token=xxx&worker=yyy&session=zzz&ts=...&sig=... URI.
+// sig must be a valid HMAC-SHA256 of the query (excluding sig itself) under
+// this install's shared secret, and ts must be within replayWindow seconds
+// of now — otherwise any web page could hijack the agent into an
+// attacker-chosen session.
 func ParseKamVDIUri(rawURI string) (*ConnectParams, error) {
 	u, err := url.Parse(rawURI)
 	if err != nil {
@@ -32,15 +46,72 @@ func ParseKamVDIUri(rawURI string) (*ConnectParams, error) {
 		return nil, fmt.Errorf("missing required parameter: token")
 	}
 
+	if err := verifyURISignature(params); err != nil {
+		return nil, err
+	}
+
+	protocol := params.Get("protocol")
+	if protocol == "" {
+		protocol = rdp.ProtocolRDP
+	}
+
 	return &ConnectParams{
 		Token:       token,
 		WorkerAddr:  params.Get("worker"),
 		SessionID:   params.Get("session"),
 		DesktopName: params.Get("name"),
 		PortalURL:   params.Get("portal"),
+		Protocol:    protocol,
 	}, nil
 }
 
+// verifyURISignature checks the sig and ts parameters of a kamvdi:// URI.
+func verifyURISignature(params url.Values) error {
+	sig := params.Get("sig")
+	if sig == "" {
+		return fmt.Errorf("missing required parameter: sig")
+	}
+
+	tsStr := params.Get("ts")
+	if tsStr == "" {
+		return fmt.Errorf("missing required parameter: ts")
+	}
+	ts, err := strconv.ParseInt(tsStr, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid ts parameter: %w", err)
+	}
+	if age := time.Now().Unix() - ts; age < -replayWindow || age > replayWindow {
+		return fmt.Errorf("URI timestamp is stale or in the future")
+	}
+
+	secret, err := loadSharedSecret()
+	if err != nil {
+		return fmt.Errorf("no shared secret provisioned; run --register first: %w", err)
+	}
+
+	canonical := url.Values{}
+	for k, v := range params {
+		if k == "sig" {
+			continue
+		}
+		canonical[k] = v
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(canonical.Encode()))
+	expected := hex.EncodeToString(mac.Sum(nil))
+
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(sig)) != 1 {
+		return fmt.Errorf("invalid URI signature")
+	}
+
+	if !checkAndRemember(sig, ts) {
+		return fmt.Errorf("URI has already been used (replay)")
+	}
+
+	return nil
+}
+
 // HandleConnect processes a kamvdi:// connect request end-to-end.
 func HandleConnect(params *ConnectParams) error {
 	name := params.DesktopName
@@ -48,35 +119,45 @@ func HandleConnect(params *ConnectParams) error {
 		name = "Desktop"
 	}
 
+	if quarantined, reason := updater.Quarantined(); quarantined {
+		notify.Show("KamVDI Agent Blocked", reason)
+		return fmt.Errorf("agent is quarantined: %s", reason)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	// 1. Notify user
 	notify.Show("KamVDI", fmt.Sprintf("Connecting to %s...", name))
 
-	// 2. Start Boundary tunnel (auto-downloads boundary.exe if needed)
+	// 2. Start Boundary tunnel
 	notify.Show("KamVDI", "Establishing secure tunnel...")
-	localPort, cmd, err := boundary.ConnectRDP(params.Token, params.WorkerAddr, params.PortalURL)
+	localPort, tunnel, err := boundary.ConnectRDP(ctx, params.Token)
 	if err != nil {
 		notify.Show("KamVDI Error", fmt.Sprintf("Failed to establish tunnel: %v", err))
 		return fmt.Errorf("boundary connect failed: %w", err)
 	}
+	defer tunnel.Close()
 
-	// 3. Launch RDP client
-	if err := rdp.LaunchDirect("127.0.0.1", localPort); err != nil {
-		notify.Show("KamVDI Error", fmt.Sprintf("Failed to launch RDP client: %v", err))
-		if cmd != nil && cmd.Process != nil {
-			cmd.Process.Kill()
-		}
-		return fmt.Errorf("RDP launch failed: %w", err)
+	// 3. Launch the native client for the requested desktop protocol
+	rdpCmd, err := rdp.LaunchDirect(rdp.ConnectParams{
+		Host:      "127.0.0.1",
+		Port:      localPort,
+		Protocol:  params.Protocol,
+		PortalURL: params.PortalURL,
+	})
+	if err != nil {
+		notify.Show("KamVDI Error", fmt.Sprintf("Failed to launch desktop client: %v", err))
+		return fmt.Errorf("desktop client launch failed: %w", err)
 	}
 
 	notify.Show("KamVDI", fmt.Sprintf("Connected to %s", name))
 
-	// 4. Start heartbeat in foreground (blocks until boundary exits)
+	// 4. Start heartbeat in foreground; it runs until the RDP client exits.
 	done := make(chan struct{})
 
 	go func() {
-		if cmd != nil {
-			cmd.Wait()
-		}
+		rdpCmd.Wait()
 		close(done)
 	}()
 
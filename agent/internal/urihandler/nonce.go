@@ -0,0 +1,100 @@
+package urihandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// replayWindow mirrors the maximum age a URI's ts parameter may have.
+const replayWindow = 60 // seconds
+
+// seenNonces tracks signatures already consumed within replayWindow. It's
+// loaded from and persisted to disk (see nonceStorePath) because each
+// kamvdi:// link click launches a brand-new agent process that parses,
+// connects, and exits (see main.go's kamvdi:// branch and
+// register_windows.go's "%1" registry command line) — an in-memory-only
+// cache would start empty on every click and never actually catch a
+// replayed link.
+var seenNonces = struct {
+	sync.Mutex
+	sigs map[string]int64
+}{sigs: loadNonceStore()}
+
+// checkAndRemember returns false if sig was already seen within
+// replayWindow; otherwise it records sig at ts, prunes stale entries, and
+// persists the result so the next kamvdi:// invocation sees it too.
+func checkAndRemember(sig string, ts int64) bool {
+	seenNonces.Lock()
+	defer seenNonces.Unlock()
+
+	for s, seenTS := range seenNonces.sigs {
+		if ts-seenTS > replayWindow {
+			delete(seenNonces.sigs, s)
+		}
+	}
+
+	if _, ok := seenNonces.sigs[sig]; ok {
+		return false
+	}
+
+	seenNonces.sigs[sig] = ts
+	saveNonceStore(seenNonces.sigs)
+	return true
+}
+
+// nonceStorePath returns the location of the on-disk replay cache. It lives
+// alongside the sealed URI secret (see secret.go) but isn't itself sealed:
+// a signature is already unforgeable HMAC output, so there's nothing
+// confidential to protect here, only a requirement that it survive past
+// this process's exit.
+func nonceStorePath() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine config dir: %w", err)
+	}
+	dir := filepath.Join(base, "kamvdi")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("cannot create config dir: %w", err)
+	}
+	return filepath.Join(dir, "seen-nonces.json"), nil
+}
+
+// loadNonceStore reads the persisted replay cache, if any. It runs once at
+// package init time; a missing or corrupt file just starts empty rather
+// than failing URI handling outright.
+func loadNonceStore() map[string]int64 {
+	path, err := nonceStorePath()
+	if err != nil {
+		return make(map[string]int64)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return make(map[string]int64)
+	}
+
+	var sigs map[string]int64
+	if err := json.Unmarshal(data, &sigs); err != nil {
+		return make(map[string]int64)
+	}
+
+	return sigs
+}
+
+// saveNonceStore persists the replay cache. Errors are swallowed: failing
+// to persist only weakens replay protection for this one click, it
+// shouldn't block a connection that already passed signature verification.
+func saveNonceStore(sigs map[string]int64) {
+	path, err := nonceStorePath()
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(sigs)
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0o600)
+}
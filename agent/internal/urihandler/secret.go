@@ -0,0 +1,125 @@
+package urihandler
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/itzik715-cmd/kamatera-vdi/agent/internal/keyring"
+)
+
+// keyringService is the keyring entry the HMAC shared secret is sealed
+// under, so kamvdi:// URIs can only be forged by something that has
+// compromised this install, not by any web page.
+const keyringService = "kamvdi-uri-secret"
+
+const sharedSecretSize = 32
+
+// EnsureSharedSecret provisions the HMAC shared secret used to authenticate
+// kamvdi:// URIs. On first run it generates a random secret and bootstraps
+// it with the portal; subsequent calls are no-ops. Called from --register.
+func EnsureSharedSecret(portalURL string) error {
+	if _, err := loadSharedSecret(); err == nil {
+		return nil // already provisioned
+	}
+
+	secret := make([]byte, sharedSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return fmt.Errorf("cannot generate shared secret: %w", err)
+	}
+
+	if portalURL != "" {
+		if err := bootstrapSecret(portalURL, secret); err != nil {
+			return fmt.Errorf("cannot bootstrap shared secret with portal: %w", err)
+		}
+	}
+
+	sealed, err := keyring.Seal(keyringService, secret)
+	if err != nil {
+		return fmt.Errorf("cannot seal shared secret in platform keyring: %w", err)
+	}
+
+	return saveSealedSecret(sealed)
+}
+
+// RemoveSharedSecret deletes the shared secret from the keyring. Called
+// from --unregister.
+func RemoveSharedSecret() error {
+	if err := keyring.Remove(keyringService); err != nil {
+		return err
+	}
+	return removeSealedSecretFile()
+}
+
+// secretPath returns the location of the sealed (encrypted) shared secret.
+func secretPath() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine config dir: %w", err)
+	}
+	dir := filepath.Join(base, "kamvdi")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("cannot create config dir: %w", err)
+	}
+	return filepath.Join(dir, "uri-secret.sealed"), nil
+}
+
+// loadSharedSecret reads and unseals the shared secret from disk.
+func loadSharedSecret() ([]byte, error) {
+	path, err := secretPath()
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return keyring.Open(keyringService, sealed)
+}
+
+func saveSealedSecret(sealed []byte) error {
+	path, err := secretPath()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, sealed, 0o600)
+}
+
+func removeSealedSecretFile() error {
+	path, err := secretPath()
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// bootstrapSecret performs a one-time POST of the freshly generated secret
+// to the portal, so it can later sign kamvdi:// links for this install.
+func bootstrapSecret(portalURL string, secret []byte) error {
+	url := portalURL + "/api/agent/bootstrap-secret"
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(url, "application/octet-stream", bytes.NewReader(secret))
+	if err != nil {
+		return fmt.Errorf("bootstrap request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bootstrap request returned HTTP %d", resp.StatusCode)
+	}
+
+	return nil
+}
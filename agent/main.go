@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
@@ -9,6 +10,7 @@ import (
 	"github.com/itzik715-cmd/kamatera-vdi/agent/internal/health"
 	"github.com/itzik715-cmd/kamatera-vdi/agent/internal/registration"
 	"github.com/itzik715-cmd/kamatera-vdi/agent/internal/tray"
+	"github.com/itzik715-cmd/kamatera-vdi/agent/internal/updater"
 	"github.com/itzik715-cmd/kamatera-vdi/agent/internal/urihandler"
 )
 
@@ -41,6 +43,25 @@ func main() {
 				fmt.Fprintf(os.Stderr, "Registration failed: %v\n", err)
 				os.Exit(1)
 			}
+			if err := health.EnsureCA(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not provision local HTTPS certificate: %v\n", err)
+			}
+			rest := os.Args[2:]
+			var portalURL string
+			if len(rest) > 0 && !strings.HasPrefix(rest[0], "--") {
+				portalURL = rest[0]
+				rest = rest[1:]
+			}
+			if err := urihandler.EnsureSharedSecret(portalURL); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not provision URI signing secret: %v\n", err)
+			}
+			if err := updater.EnsureLocalAPIToken(portalURL); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not provision local API token: %v\n", err)
+			}
+			if err := config.SavePortalURL(portalURL); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not persist portal URL: %v\n", err)
+			}
+			applyConfigFlags(rest)
 			fmt.Println("URI scheme registered successfully")
 			return
 
@@ -49,12 +70,47 @@ func main() {
 				fmt.Fprintf(os.Stderr, "Unregistration failed: %v\n", err)
 				os.Exit(1)
 			}
+			if err := health.RemoveCA(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not remove local HTTPS certificate: %v\n", err)
+			}
+			if err := urihandler.RemoveSharedSecret(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not remove URI signing secret: %v\n", err)
+			}
+			if err := updater.RemoveLocalAPIToken(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not remove local API token: %v\n", err)
+			}
 			fmt.Println("URI scheme unregistered")
 			return
 
 		case arg == "--version":
 			fmt.Printf("KamVDI Agent %s\n", Version)
 			return
+
+		case arg == "update":
+			opts := updater.UpdateOptions{}
+			for _, extra := range os.Args[2:] {
+				switch {
+				case extra == "--force":
+					opts.Force = true
+				case extra == "--dry-run":
+					opts.DryRun = true
+				case strings.HasPrefix(extra, "--channel="):
+					opts.Channel = strings.TrimPrefix(extra, "--channel=")
+				case strings.HasPrefix(extra, "--version="):
+					opts.Version = strings.TrimPrefix(extra, "--version=")
+				}
+			}
+			if err := updater.RunUpdate(context.Background(), config.LoadPortalURL(), opts); err != nil {
+				fmt.Fprintf(os.Stderr, "Update failed: %v\n", err)
+				os.Exit(1)
+			}
+			return
+
+		case arg == "config":
+			applyConfigFlags(os.Args[2:])
+			fmt.Printf("auto-apply=%v channel=%s pin=%s\n",
+				config.AutoApply(), config.UpdateChannel(), config.PinnedVersion())
+			return
 		}
 	}
 
@@ -66,6 +122,38 @@ func main() {
 	// Start local health server so the browser can detect the agent
 	health.Start()
 
+	// Start background update checks, if a portal URL has been registered
+	updater.StartBackgroundCheck(config.LoadPortalURL())
+
 	// Default: run as system tray application
 	tray.Run()
 }
+
+// applyConfigFlags handles the --auto-apply/--no-auto-apply, --channel= and
+// --pin=/--clear-pin flags shared by --register and the `config` subcommand.
+func applyConfigFlags(args []string) {
+	for _, arg := range args {
+		switch {
+		case arg == "--auto-apply":
+			if err := config.SetAutoApply(true); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not enable auto-apply: %v\n", err)
+			}
+		case arg == "--no-auto-apply":
+			if err := config.SetAutoApply(false); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not disable auto-apply: %v\n", err)
+			}
+		case strings.HasPrefix(arg, "--channel="):
+			if err := config.SetUpdateChannel(strings.TrimPrefix(arg, "--channel=")); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not set update channel: %v\n", err)
+			}
+		case arg == "--clear-pin":
+			if err := config.SetPinnedVersion(""); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not clear pinned version: %v\n", err)
+			}
+		case strings.HasPrefix(arg, "--pin="):
+			if err := config.SetPinnedVersion(strings.TrimPrefix(arg, "--pin=")); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not set pinned version: %v\n", err)
+			}
+		}
+	}
+}